@@ -0,0 +1,255 @@
+// Package ast provides a typed Go AST on top of the raw tree-sitter CST
+// produced by the phpx/phpx_only grammar bindings, modelled on
+// z7zmey/php-parser's node set.
+package ast
+
+// Range is a byte range into the original source, preserved through
+// lowering so nodes can be round-tripped back to source.
+type Range struct {
+	StartByte, EndByte uint
+}
+
+// Node is implemented by every AST node. Kind is a diagnostic label
+// ("Class", "UseStmt", ...), not an identifier.
+type Node interface {
+	Range() Range
+	Kind() string
+}
+
+// Pos embeds into concrete node types to satisfy the Range() half of Node.
+type Pos struct {
+	R Range
+}
+
+func (p Pos) Range() Range { return p.R }
+
+// File is the root of a parsed PHPX source file.
+type File struct {
+	Pos
+	Namespace *Namespace
+	Decls     []Decl
+}
+
+func (*File) Kind() string { return "File" }
+
+// Decl is a top-level or namespace-level declaration.
+type Decl interface {
+	Node
+	declNode()
+}
+
+// Namespace is a `namespace Foo\Bar;` or `namespace Foo\Bar { ... }` block.
+type Namespace struct {
+	Pos
+	Name  string
+	Uses  []*UseStmt
+	Decls []Decl
+}
+
+func (*Namespace) Kind() string { return "Namespace" }
+func (*Namespace) declNode()    {}
+
+// UseStmt is a `use`, `use function`, or `use const` import.
+type UseStmt struct {
+	Pos
+	ImportKind UseKind
+	Path       string
+	Alias      string // empty if no `as` clause
+}
+
+type UseKind int
+
+const (
+	UseClass UseKind = iota
+	UseFunction
+	UseConst
+)
+
+func (*UseStmt) Kind() string { return "UseStmt" }
+func (*UseStmt) declNode()    {}
+
+// Class, Interface, Trait, and Enum share a body shape; PHPX keeps them as
+// distinct node types (rather than one "ClassLike") to mirror the grammar.
+type Class struct {
+	Pos
+	Name       string
+	Extends    string
+	Implements []string
+	Abstract   bool
+	Final      bool
+	Members    []Decl
+}
+
+func (*Class) Kind() string { return "Class" }
+func (*Class) declNode()    {}
+
+type Interface struct {
+	Pos
+	Name    string
+	Extends []string
+	Members []Decl
+}
+
+func (*Interface) Kind() string { return "Interface" }
+func (*Interface) declNode()    {}
+
+type Trait struct {
+	Pos
+	Name    string
+	Members []Decl
+}
+
+func (*Trait) Kind() string { return "Trait" }
+func (*Trait) declNode()    {}
+
+type Enum struct {
+	Pos
+	Name       string
+	BackedType string // "", "int", or "string"
+	Implements []string
+	Members    []Decl
+}
+
+func (*Enum) Kind() string { return "Enum" }
+func (*Enum) declNode()    {}
+
+// Function is a top-level or namespaced `function` declaration.
+type Function struct {
+	Pos
+	Name       string
+	Params     []*Param
+	ReturnType string
+	Body       *Block
+}
+
+func (*Function) Kind() string { return "Function" }
+func (*Function) declNode()    {}
+
+// Method is a class/interface/trait member function.
+type Method struct {
+	Pos
+	Name       string
+	Params     []*Param
+	ReturnType string
+	Static     bool
+	Visibility Visibility
+	Abstract   bool
+	Body       *Block // nil for abstract/interface methods
+}
+
+func (*Method) Kind() string { return "Method" }
+func (*Method) declNode()    {}
+
+// Property is a class property declaration.
+type Property struct {
+	Pos
+	Name       string
+	Type       string
+	Static     bool
+	Visibility Visibility
+	Default    Expr // nil if unset
+}
+
+func (*Property) Kind() string { return "Property" }
+func (*Property) declNode()    {}
+
+// Const is a class or namespace `const` declaration.
+type Const struct {
+	Pos
+	Name       string
+	Value      Expr
+	Visibility Visibility
+}
+
+func (*Const) Kind() string { return "Const" }
+func (*Const) declNode()    {}
+
+// PHPXComponent is the JSX-like `<Foo ...>...</Foo>` markup that PHPX adds
+// on top of plain PHP.
+type PHPXComponent struct {
+	Pos
+	Tag        string
+	Attributes []*PHPXAttribute
+	Children   []Node
+	SelfClosed bool
+}
+
+func (*PHPXComponent) Kind() string { return "PHPXComponent" }
+func (*PHPXComponent) declNode()    {}
+
+type PHPXAttribute struct {
+	Pos
+	Name  string
+	Value Expr // nil for boolean attributes
+}
+
+func (*PHPXAttribute) Kind() string { return "PHPXAttribute" }
+
+type Visibility int
+
+const (
+	VisibilityPublic Visibility = iota
+	VisibilityProtected
+	VisibilityPrivate
+)
+
+// Param is a function/method parameter.
+type Param struct {
+	Pos
+	Name     string
+	Type     string
+	Default  Expr // nil if unset
+	ByRef    bool
+	Variadic bool
+}
+
+func (*Param) Kind() string { return "Param" }
+
+// Block is a `{ ... }` statement list.
+type Block struct {
+	Pos
+	Stmts []Stmt
+}
+
+func (*Block) Kind() string { return "Block" }
+func (*Block) stmtNode()    {}
+
+// Stmt is a statement.
+type Stmt interface {
+	Node
+	stmtNode()
+}
+
+// Expr is an expression.
+type Expr interface {
+	Node
+	exprNode()
+}
+
+// Name is a (possibly qualified) reference to a class, function, or
+// constant, as written in source. NamespaceResolver rewrites these in
+// place to their fully-qualified form.
+type Name struct {
+	Pos
+	Qualified string
+	Resolved  string // set by NamespaceResolver; empty until then
+}
+
+func (*Name) Kind() string { return "Name" }
+func (*Name) exprNode()    {}
+
+// Raw is a catch-all for statement/expression forms lowering doesn't yet
+// give a dedicated type (arbitrary CST node kinds like expression
+// statements, binary expressions, etc.). It keeps the original node kind,
+// byte range, and any Name references found inside it, so unmodeled code
+// still round-trips and still participates in namespace resolution
+// instead of silently disappearing from the tree.
+type Raw struct {
+	Pos
+	RawKind string
+	Names   []*Name
+}
+
+func (r *Raw) Kind() string { return r.RawKind }
+func (*Raw) stmtNode()      {}
+func (*Raw) exprNode()      {}
@@ -0,0 +1,341 @@
+package ast
+
+import tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+// Lower converts a parsed tree-sitter CST (from phpx.Language() or
+// phpx_only.Language()) into a *File. Byte ranges from the CST are copied onto
+// every lowered node so callers can slice src to recover the original text.
+//
+// namespace_definition has two shapes: bracketed (`namespace Foo { ... }`),
+// whose own body holds every member, and unbracketed (`namespace Foo;`),
+// which has no body of its own — every root-level sibling that follows it
+// belongs to that namespace instead of to the file, until the next
+// namespace_definition (if any). Lower tracks that open, unbracketed
+// namespace across root-level siblings to route those into it rather than
+// into File.Decls.
+func Lower(tree *tree_sitter.Tree, src []byte) *File {
+	root := tree.RootNode()
+	f := &File{Pos: posOf(root)}
+
+	var openNamespace *Namespace
+	for i := uint(0); i < root.ChildCount(); i++ {
+		child := root.Child(i)
+		switch child.Kind() {
+		case "namespace_definition":
+			ns := lowerNamespace(child, src)
+			f.Namespace = ns
+			if isBracketedNamespace(child) {
+				openNamespace = nil
+			} else {
+				openNamespace = ns
+			}
+		case "use_declaration":
+			if openNamespace != nil {
+				openNamespace.Uses = append(openNamespace.Uses, lowerUse(child, src))
+			}
+		default:
+			if openNamespace != nil {
+				if d := lowerDecl(child, src); d != nil {
+					openNamespace.Decls = append(openNamespace.Decls, d)
+				}
+				continue
+			}
+			if d := lowerDecl(child, src); d != nil {
+				f.Decls = append(f.Decls, d)
+			}
+		}
+	}
+	return f
+}
+
+func posOf(n *tree_sitter.Node) Pos {
+	return Pos{R: Range{StartByte: n.StartByte(), EndByte: n.EndByte()}}
+}
+
+// isBracketedNamespace reports whether n ("namespace Foo { ... }") has its
+// own compound_statement body, as opposed to the unbracketed
+// ("namespace Foo;") form, whose body field is just the terminating `;`.
+func isBracketedNamespace(n *tree_sitter.Node) bool {
+	body := n.ChildByFieldName("body")
+	return body != nil && body.Kind() == "compound_statement"
+}
+
+func lowerNamespace(n *tree_sitter.Node, src []byte) *Namespace {
+	ns := &Namespace{Pos: posOf(n)}
+	if name := n.ChildByFieldName("name"); name != nil {
+		ns.Name = string(src[name.StartByte():name.EndByte()])
+	}
+	body := n.ChildByFieldName("body")
+	if body == nil || body.Kind() != "compound_statement" {
+		return ns
+	}
+	for i := uint(0); i < body.ChildCount(); i++ {
+		child := body.Child(i)
+		switch child.Kind() {
+		case "{", "}":
+			continue
+		case "use_declaration":
+			ns.Uses = append(ns.Uses, lowerUse(child, src))
+		default:
+			if d := lowerDecl(child, src); d != nil {
+				ns.Decls = append(ns.Decls, d)
+			}
+		}
+	}
+	return ns
+}
+
+func lowerUse(n *tree_sitter.Node, src []byte) *UseStmt {
+	u := &UseStmt{Pos: posOf(n)}
+	if kind := n.ChildByFieldName("kind"); kind != nil {
+		switch string(src[kind.StartByte():kind.EndByte()]) {
+		case "function":
+			u.ImportKind = UseFunction
+		case "const":
+			u.ImportKind = UseConst
+		}
+	}
+	if path := n.ChildByFieldName("path"); path != nil {
+		u.Path = string(src[path.StartByte():path.EndByte()])
+	}
+	if alias := n.ChildByFieldName("alias"); alias != nil {
+		u.Alias = string(src[alias.StartByte():alias.EndByte()])
+	}
+	return u
+}
+
+// lowerDecl lowers a single top-level/namespace-level/class-body node. It
+// returns nil for nodes that carry no declaration semantics (punctuation,
+// comments, etc.) so callers can skip them without a type switch of their
+// own.
+func lowerDecl(n *tree_sitter.Node, src []byte) Decl {
+	switch n.Kind() {
+	case "class_declaration":
+		return lowerClass(n, src)
+	case "interface_declaration":
+		return lowerInterface(n, src)
+	case "trait_declaration":
+		return lowerTrait(n, src)
+	case "enum_declaration":
+		return lowerEnum(n, src)
+	case "function_definition":
+		return lowerFunction(n, src)
+	case "method_declaration":
+		return lowerMethod(n, src)
+	case "property_declaration":
+		return lowerProperty(n, src)
+	case "const_declaration":
+		return lowerConst(n, src)
+	case "phpx_element", "phpx_self_closing_element":
+		return lowerPHPXComponent(n, src)
+	default:
+		return nil
+	}
+}
+
+func fieldText(n *tree_sitter.Node, field string, src []byte) string {
+	if c := n.ChildByFieldName(field); c != nil {
+		return string(src[c.StartByte():c.EndByte()])
+	}
+	return ""
+}
+
+func lowerClass(n *tree_sitter.Node, src []byte) *Class {
+	c := &Class{Pos: posOf(n), Name: fieldText(n, "name", src), Extends: fieldText(n, "extends", src)}
+	if body := n.ChildByFieldName("body"); body != nil {
+		for i := uint(0); i < body.ChildCount(); i++ {
+			if d := lowerDecl(body.Child(i), src); d != nil {
+				c.Members = append(c.Members, d)
+			}
+		}
+	}
+	return c
+}
+
+func lowerInterface(n *tree_sitter.Node, src []byte) *Interface {
+	i := &Interface{Pos: posOf(n), Name: fieldText(n, "name", src)}
+	if body := n.ChildByFieldName("body"); body != nil {
+		for j := uint(0); j < body.ChildCount(); j++ {
+			if d := lowerDecl(body.Child(j), src); d != nil {
+				i.Members = append(i.Members, d)
+			}
+		}
+	}
+	return i
+}
+
+func lowerTrait(n *tree_sitter.Node, src []byte) *Trait {
+	t := &Trait{Pos: posOf(n), Name: fieldText(n, "name", src)}
+	if body := n.ChildByFieldName("body"); body != nil {
+		for i := uint(0); i < body.ChildCount(); i++ {
+			if d := lowerDecl(body.Child(i), src); d != nil {
+				t.Members = append(t.Members, d)
+			}
+		}
+	}
+	return t
+}
+
+func lowerEnum(n *tree_sitter.Node, src []byte) *Enum {
+	e := &Enum{Pos: posOf(n), Name: fieldText(n, "name", src), BackedType: fieldText(n, "backed_type", src)}
+	if body := n.ChildByFieldName("body"); body != nil {
+		for i := uint(0); i < body.ChildCount(); i++ {
+			if d := lowerDecl(body.Child(i), src); d != nil {
+				e.Members = append(e.Members, d)
+			}
+		}
+	}
+	return e
+}
+
+func lowerFunction(n *tree_sitter.Node, src []byte) *Function {
+	return &Function{
+		Pos:        posOf(n),
+		Name:       fieldText(n, "name", src),
+		ReturnType: fieldText(n, "return_type", src),
+		Params:     lowerParams(n.ChildByFieldName("parameters"), src),
+		Body:       lowerBlock(n.ChildByFieldName("body"), src),
+	}
+}
+
+func lowerMethod(n *tree_sitter.Node, src []byte) *Method {
+	return &Method{
+		Pos:        posOf(n),
+		Name:       fieldText(n, "name", src),
+		ReturnType: fieldText(n, "return_type", src),
+		Params:     lowerParams(n.ChildByFieldName("parameters"), src),
+		Body:       lowerBlock(n.ChildByFieldName("body"), src),
+	}
+}
+
+func lowerParams(n *tree_sitter.Node, src []byte) []*Param {
+	if n == nil {
+		return nil
+	}
+	var params []*Param
+	for i := uint(0); i < n.ChildCount(); i++ {
+		child := n.Child(i)
+		if child.Kind() != "simple_parameter" && child.Kind() != "variadic_parameter" {
+			continue
+		}
+		params = append(params, &Param{
+			Pos:      posOf(child),
+			Name:     fieldText(child, "name", src),
+			Type:     fieldText(child, "type", src),
+			Variadic: child.Kind() == "variadic_parameter",
+		})
+	}
+	return params
+}
+
+func lowerProperty(n *tree_sitter.Node, src []byte) *Property {
+	return &Property{
+		Pos:     posOf(n),
+		Name:    fieldText(n, "name", src),
+		Type:    fieldText(n, "type", src),
+		Default: lowerExpr(n.ChildByFieldName("default"), src),
+	}
+}
+
+func lowerConst(n *tree_sitter.Node, src []byte) *Const {
+	return &Const{
+		Pos:   posOf(n),
+		Name:  fieldText(n, "name", src),
+		Value: lowerExpr(n.ChildByFieldName("value"), src),
+	}
+}
+
+// lowerBlock lowers every statement in a compound_statement's body. Each
+// statement not given a dedicated type elsewhere in this file becomes a
+// *Raw, preserving its range and any Name references it contains rather
+// than being dropped.
+func lowerBlock(n *tree_sitter.Node, src []byte) *Block {
+	if n == nil {
+		return nil
+	}
+	b := &Block{Pos: posOf(n)}
+	for i := uint(0); i < n.ChildCount(); i++ {
+		child := n.Child(i)
+		if child.Kind() == "{" || child.Kind() == "}" {
+			continue
+		}
+		if s := lowerStmt(child, src); s != nil {
+			b.Stmts = append(b.Stmts, s)
+		}
+	}
+	return b
+}
+
+// lowerStmt lowers one statement. A bare nested compound_statement
+// (`{ ... }` used as a statement) recurses into lowerBlock so its own
+// statements are still visible to Walker; everything else, including an
+// if/while/etc. together with its body, becomes a single *Raw carrying
+// every name referenced anywhere in its subtree.
+func lowerStmt(n *tree_sitter.Node, src []byte) Stmt {
+	if n.Kind() == "compound_statement" {
+		return lowerBlock(n, src)
+	}
+	return &Raw{Pos: posOf(n), RawKind: n.Kind(), Names: extractNames(n, src)}
+}
+
+// lowerExpr lowers a single expression field to a *Raw, or returns a nil
+// Expr if field is nil — callers must not assign lowerExpr's zero value
+// through a concrete pointer, or the nil would stop comparing equal to nil
+// once boxed in the Expr interface.
+func lowerExpr(field *tree_sitter.Node, src []byte) Expr {
+	if field == nil {
+		return nil
+	}
+	return &Raw{Pos: posOf(field), RawKind: field.Kind(), Names: extractNames(field, src)}
+}
+
+// extractNames walks n's subtree for `name`/`qualified_name` tokens (class,
+// function, and constant references) and lowers each to a *Name, so
+// NamespaceResolver has real nodes to resolve against real source instead
+// of only the hand-built trees in tests.
+func extractNames(n *tree_sitter.Node, src []byte) []*Name {
+	var names []*Name
+	var walk func(*tree_sitter.Node)
+	walk = func(c *tree_sitter.Node) {
+		switch c.Kind() {
+		case "name", "qualified_name":
+			names = append(names, &Name{Pos: posOf(c), Qualified: string(src[c.StartByte():c.EndByte()])})
+			return
+		}
+		for i := uint(0); i < c.ChildCount(); i++ {
+			walk(c.Child(i))
+		}
+	}
+	walk(n)
+	return names
+}
+
+func lowerPHPXComponent(n *tree_sitter.Node, src []byte) *PHPXComponent {
+	c := &PHPXComponent{
+		Pos:        posOf(n),
+		Tag:        fieldText(n, "tag", src),
+		SelfClosed: n.Kind() == "phpx_self_closing_element",
+	}
+	for i := uint(0); i < n.ChildCount(); i++ {
+		child := n.Child(i)
+		switch child.Kind() {
+		case "phpx_attribute":
+			c.Attributes = append(c.Attributes, lowerPHPXAttribute(child, src))
+		case "phpx_element", "phpx_self_closing_element":
+			c.Children = append(c.Children, lowerPHPXComponent(child, src))
+		case "phpx_text":
+			c.Children = append(c.Children, &Raw{Pos: posOf(child), RawKind: "phpx_text"})
+		case "phpx_expression":
+			c.Children = append(c.Children, &Raw{Pos: posOf(child), RawKind: "phpx_expression", Names: extractNames(child, src)})
+		}
+	}
+	return c
+}
+
+func lowerPHPXAttribute(n *tree_sitter.Node, src []byte) *PHPXAttribute {
+	return &PHPXAttribute{
+		Pos:   posOf(n),
+		Name:  fieldText(n, "name", src),
+		Value: lowerExpr(n.ChildByFieldName("value"), src),
+	}
+}
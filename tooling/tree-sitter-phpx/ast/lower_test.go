@@ -0,0 +1,81 @@
+package ast
+
+import (
+	"testing"
+
+	"github.com/samifouad/deka/tooling/tree-sitter-phpx/bindings/go/phpx"
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// TestLowerAndResolveRealSource runs the full Lower -> Walker ->
+// NamespaceResolver pipeline over real PHPX source, so a Name reference
+// found inside a function body actually gets resolved against the
+// namespace/use context instead of only exercising the resolver against a
+// hand-built tree (see resolver_test.go).
+func TestLowerAndResolveRealSource(t *testing.T) {
+	language := tree_sitter.NewLanguage(phpx.Language())
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(language)
+
+	src := []byte(`<?php
+namespace App\Http;
+
+use App\Models\User;
+
+function handle() {
+    return User::find(1);
+}
+`)
+	tree := parser.Parse(src, nil)
+	if tree == nil || tree.RootNode().HasError() {
+		t.Fatalf("failed to parse source under test")
+	}
+
+	file := Lower(tree, src)
+	if file.Namespace == nil {
+		t.Fatalf("Lower did not produce a namespace")
+	}
+
+	var fn *Function
+	for _, d := range file.Namespace.Decls {
+		if f, ok := d.(*Function); ok {
+			fn = f
+		}
+	}
+	if fn == nil {
+		t.Fatalf("Lower did not produce the handle() function")
+	}
+	if fn.Body == nil || len(fn.Body.Stmts) == 0 {
+		t.Fatalf("Lower produced an empty function body; want at least one statement")
+	}
+
+	r := NewNamespaceResolver()
+	NewWalker(r).Walk(file)
+
+	var resolved []string
+	v := &collectNames{add: func(n *Name) { resolved = append(resolved, n.Resolved) }}
+	NewWalker(v).Walk(file)
+
+	found := false
+	for _, r := range resolved {
+		if r == "App\\Models\\User" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("no Name resolved to App\\Models\\User among real-source names; got %v", resolved)
+	}
+}
+
+type collectNames struct {
+	BaseVisitor
+	add func(*Name)
+}
+
+func (c *collectNames) Enter(n Node) bool {
+	if name, ok := n.(*Name); ok {
+		c.add(name)
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package ast
+
+import "strings"
+
+// NamespaceResolver is a Visitor that binds every Name to its
+// fully-qualified form, given the enclosing namespace and its `use`
+// imports. `use function` and `use const` imports are recorded but not
+// consulted: every Name resolves against class imports and the current
+// namespace only. Run it with a Walker before relying on Name.Resolved.
+type NamespaceResolver struct {
+	BaseVisitor
+
+	currentNamespace string
+	classUses        map[string]string // alias -> fully-qualified path
+	functionUses     map[string]string
+	constUses        map[string]string
+}
+
+// NewNamespaceResolver returns a ready-to-use resolver.
+func NewNamespaceResolver() *NamespaceResolver {
+	return &NamespaceResolver{
+		classUses:    map[string]string{},
+		functionUses: map[string]string{},
+		constUses:    map[string]string{},
+	}
+}
+
+func (r *NamespaceResolver) Enter(n Node) bool {
+	switch n := n.(type) {
+	case *Namespace:
+		r.currentNamespace = n.Name
+		for _, u := range n.Uses {
+			alias := u.Alias
+			if alias == "" {
+				alias = lastSegment(u.Path)
+			}
+			switch u.ImportKind {
+			case UseFunction:
+				r.functionUses[alias] = u.Path
+			case UseConst:
+				r.constUses[alias] = u.Path
+			default:
+				r.classUses[alias] = u.Path
+			}
+		}
+	case *Name:
+		n.Resolved = r.resolve(n.Qualified)
+	}
+	return false
+}
+
+// resolve applies PHP's class-name resolution rules: fully qualified names
+// (leading `\`) pass through with the `\` removed, a name whose first
+// segment (the whole name, if unqualified) matches an imported class alias
+// resolves against that import, and any other name is prefixed with the
+// current namespace.
+func (r *NamespaceResolver) resolve(name string) string {
+	if strings.HasPrefix(name, "\\") {
+		return strings.TrimPrefix(name, "\\")
+	}
+
+	segments := strings.SplitN(name, "\\", 2)
+	if fq, ok := r.classUses[segments[0]]; ok {
+		if len(segments) == 1 {
+			return fq
+		}
+		return fq + "\\" + segments[1]
+	}
+
+	if r.currentNamespace == "" {
+		return name
+	}
+	return r.currentNamespace + "\\" + name
+}
+
+func lastSegment(path string) string {
+	if i := strings.LastIndex(path, "\\"); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
@@ -0,0 +1,50 @@
+package ast
+
+import "testing"
+
+func TestNamespaceResolver(t *testing.T) {
+	file := &File{
+		Namespace: &Namespace{
+			Name: "App\\Http",
+			Uses: []*UseStmt{
+				{Path: "App\\Models\\User", ImportKind: UseClass},
+				{Path: "App\\Models\\Post", ImportKind: UseClass, Alias: "BlogPost"},
+			},
+			Decls: []Decl{
+				&Const{Name: "X"},
+			},
+		},
+	}
+	file.Namespace.Decls = append(file.Namespace.Decls, nameDecl("User"), nameDecl("BlogPost"), nameDecl("Controller"), nameDecl("\\Fully\\Qualified"))
+
+	r := NewNamespaceResolver()
+	NewWalker(r).Walk(file)
+
+	got := map[string]string{}
+	for _, d := range file.Namespace.Decls {
+		if c, ok := d.(*Const); ok {
+			if nm, ok := c.Value.(*Name); ok {
+				got[nm.Qualified] = nm.Resolved
+			}
+		}
+	}
+
+	want := map[string]string{
+		"User":               "App\\Models\\User",
+		"BlogPost":           "App\\Models\\Post",
+		"Controller":         "App\\Http\\Controller",
+		"\\Fully\\Qualified": "Fully\\Qualified",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("resolve(%q) = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+// nameDecl parks a *Name (an Expr) in a namespace body as the value of a
+// Const, so the Walker reaches it through Const.Value and the resolver sees
+// a real *Name without a real parse tree.
+func nameDecl(qualified string) *Const {
+	return &Const{Value: &Name{Qualified: qualified}}
+}
@@ -0,0 +1,100 @@
+package ast
+
+// Visitor is implemented by callers that want to traverse an AST. Enter is
+// called before a node's children are visited and may return skip=true to
+// prevent descending into the subtree; Leave is called after (and is
+// skipped for subtrees that were skipped in Enter).
+type Visitor interface {
+	Enter(n Node) (skip bool)
+	Leave(n Node)
+}
+
+// Walker drives a Visitor over an AST rooted at a File.
+type Walker struct {
+	Visitor Visitor
+}
+
+// NewWalker returns a Walker that drives v.
+func NewWalker(v Visitor) *Walker {
+	return &Walker{Visitor: v}
+}
+
+// Walk visits n and its descendants in source order.
+func (w *Walker) Walk(n Node) {
+	if n == nil {
+		return
+	}
+	if w.Visitor.Enter(n) {
+		return
+	}
+	defer w.Visitor.Leave(n)
+
+	switch n := n.(type) {
+	case *File:
+		if n.Namespace != nil {
+			w.Walk(n.Namespace)
+		}
+		w.walkDecls(n.Decls)
+	case *Namespace:
+		for _, u := range n.Uses {
+			w.Walk(u)
+		}
+		w.walkDecls(n.Decls)
+	case *Class:
+		w.walkDecls(n.Members)
+	case *Interface:
+		w.walkDecls(n.Members)
+	case *Trait:
+		w.walkDecls(n.Members)
+	case *Enum:
+		w.walkDecls(n.Members)
+	case *Function:
+		for _, p := range n.Params {
+			w.Walk(p)
+		}
+		if n.Body != nil {
+			w.Walk(n.Body)
+		}
+	case *Method:
+		for _, p := range n.Params {
+			w.Walk(p)
+		}
+		if n.Body != nil {
+			w.Walk(n.Body)
+		}
+	case *Property:
+		w.Walk(n.Default)
+	case *Const:
+		w.Walk(n.Value)
+	case *Block:
+		for _, s := range n.Stmts {
+			w.Walk(s)
+		}
+	case *PHPXComponent:
+		for _, a := range n.Attributes {
+			w.Walk(a)
+		}
+		for _, c := range n.Children {
+			w.Walk(c)
+		}
+	case *PHPXAttribute:
+		w.Walk(n.Value)
+	case *Raw:
+		for _, nm := range n.Names {
+			w.Walk(nm)
+		}
+	}
+}
+
+func (w *Walker) walkDecls(decls []Decl) {
+	for _, d := range decls {
+		w.Walk(d)
+	}
+}
+
+// BaseVisitor is embedded by visitors that only care about a subset of
+// node kinds; its Enter/Leave are no-ops.
+type BaseVisitor struct{}
+
+func (BaseVisitor) Enter(Node) bool { return false }
+func (BaseVisitor) Leave(Node)      {}
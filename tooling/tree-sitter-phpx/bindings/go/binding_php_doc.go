@@ -0,0 +1,13 @@
+package tree_sitter_phpx
+
+// #cgo CFLAGS: -I../../phpdoc/src -std=c11 -fPIC
+// #include "../../phpdoc/src/parser.c"
+// #include "../../phpdoc/src/scanner.c"
+import "C"
+
+import "unsafe"
+
+// Get the tree-sitter Language for PHPDoc docblocks (/** ... */).
+func LanguagePHPDoc() unsafe.Pointer {
+	return unsafe.Pointer(C.tree_sitter_phpdoc())
+}
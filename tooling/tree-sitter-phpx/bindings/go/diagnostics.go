@@ -0,0 +1,174 @@
+package tree_sitter_phpx
+
+import (
+	"bytes"
+	"fmt"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// Severity classifies a Diagnostic.
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// Diagnostic describes one syntax problem found while walking a parsed
+// tree's ERROR/MISSING nodes.
+type Diagnostic struct {
+	StartByte, EndByte uint
+	Line, Column       uint   // 0-based, derived from src at StartByte
+	Rule               string // name of the grammar rule containing the error
+	Expected           string // best-effort expected-symbol hint; "" if unknown
+	Severity           Severity
+	Message            string
+}
+
+// Diagnose walks tree's ERROR and MISSING nodes and produces structured
+// diagnostics. Unlike RootNode().HasError(), this keeps enough context
+// (position, enclosing rule, expected symbol) for an analyzer or editor to
+// surface a useful message instead of a bare parse failure.
+func Diagnose(tree *tree_sitter.Tree, src []byte) []Diagnostic {
+	var diags []Diagnostic
+	var walk func(n *tree_sitter.Node, rule string)
+	walk = func(n *tree_sitter.Node, rule string) {
+		switch {
+		case n.IsMissing():
+			diags = append(diags, newDiagnostic(n, src, rule, SeverityError,
+				fmt.Sprintf("missing %s", n.Kind())))
+		case n.IsError():
+			diags = append(diags, newDiagnostic(n, src, rule, SeverityError,
+				fmt.Sprintf("unexpected %s", tokenPreview(n, src))))
+		}
+
+		childRule := rule
+		if n.IsNamed() && !n.IsError() {
+			childRule = n.Kind()
+		}
+		for i := uint(0); i < n.ChildCount(); i++ {
+			walk(n.Child(i), childRule)
+		}
+	}
+	walk(tree.RootNode(), tree.RootNode().Kind())
+	return diags
+}
+
+func newDiagnostic(n *tree_sitter.Node, src []byte, rule string, sev Severity, msg string) Diagnostic {
+	line, col := lineCol(src, n.StartByte())
+	return Diagnostic{
+		StartByte: n.StartByte(),
+		EndByte:   n.EndByte(),
+		Line:      line,
+		Column:    col,
+		Rule:      rule,
+		Expected:  expectedSymbol(n),
+		Severity:  sev,
+		Message:   msg,
+	}
+}
+
+func lineCol(src []byte, pos uint) (line, col uint) {
+	for _, b := range src[:pos] {
+		if b == '\n' {
+			line++
+			col = 0
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
+
+func tokenPreview(n *tree_sitter.Node, src []byte) string {
+	text := bytes.TrimSpace(src[n.StartByte():n.EndByte()])
+	if len(text) == 0 {
+		return n.Kind()
+	}
+	if len(text) > 20 {
+		text = append(text[:20:20], "..."...)
+	}
+	return string(text)
+}
+
+// expectedSymbol derives a hint for what the grammar wanted at this
+// position. MISSING nodes are generated by tree-sitter's error-recovery
+// directly from the parse table, so their own kind is already that hint;
+// plain ERROR nodes carry no such information from the parse table.
+func expectedSymbol(n *tree_sitter.Node) string {
+	if n.IsMissing() {
+		return n.Kind()
+	}
+	return ""
+}
+
+// ParseWithRecovery parses src and, if the result contains errors, blanks
+// out each erroneous span up to its next statement boundary (`;`, `}`,
+// `<?php`, or `?>`) and reparses, so the returned tree is a genuinely
+// recovered tree (the surrounding code parses clean around the blanked gap)
+// rather than the same ERROR/MISSING-carrying tree the first parse
+// produced. Diagnostics are still reported against the original src, with
+// EndByte widened to the statement boundary that was blanked.
+func ParseWithRecovery(parser *tree_sitter.Parser, src []byte) (*tree_sitter.Tree, []Diagnostic) {
+	tree := parser.Parse(src, nil)
+	if tree == nil {
+		return nil, []Diagnostic{{Message: "failed to produce a parse tree", Severity: SeverityError}}
+	}
+
+	diags := Diagnose(tree, src)
+	if len(diags) == 0 {
+		return tree, nil
+	}
+
+	recovered := append([]byte(nil), src...)
+	for i := range diags {
+		boundary, ok := nextStatementBoundary(src, diags[i].EndByte)
+		if !ok {
+			continue
+		}
+		diags[i].EndByte = boundary
+		blank(recovered, diags[i].StartByte, boundary)
+	}
+
+	if resynced := parser.Parse(recovered, nil); resynced != nil {
+		tree = resynced
+	}
+
+	return tree, diags
+}
+
+// blank overwrites src[start:end] with spaces so the blanked span reparses
+// as empty rather than as garbage tokens, except for newlines, which are
+// kept so every byte offset and line number still lines up with the
+// original source.
+func blank(src []byte, start, end uint) {
+	for i := start; i < end; i++ {
+		if src[i] != '\n' {
+			src[i] = ' '
+		}
+	}
+}
+
+var statementBoundaries = [][]byte{[]byte(";"), []byte("}"), []byte("<?php"), []byte("?>")}
+
+func nextStatementBoundary(src []byte, from uint) (uint, bool) {
+	rest := src[from:]
+	best := -1
+	for _, b := range statementBoundaries {
+		if i := bytes.Index(rest, b); i >= 0 && (best == -1 || i < best) {
+			best = i + len(b)
+		}
+	}
+	if best == -1 {
+		return 0, false
+	}
+	return from + uint(best), true
+}
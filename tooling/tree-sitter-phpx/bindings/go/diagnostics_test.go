@@ -0,0 +1,95 @@
+package tree_sitter_phpx_test
+
+import (
+	"testing"
+
+	tree_sitter_phpx "github.com/samifouad/deka/tooling/tree-sitter-phpx/bindings/go"
+	"github.com/samifouad/deka/tooling/tree-sitter-phpx/bindings/go/phpx"
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+func TestDiagnoseCleanSource(t *testing.T) {
+	language := tree_sitter.NewLanguage(phpx.Language())
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(language)
+
+	sourceCode := []byte("<?php echo 'Hello, World!';")
+	tree := parser.Parse(sourceCode, nil)
+	if tree == nil {
+		t.Fatalf("failed to parse source under test")
+	}
+
+	if diags := tree_sitter_phpx.Diagnose(tree, sourceCode); len(diags) != 0 {
+		t.Errorf("Diagnose on valid source returned %d diagnostics, want 0: %+v", len(diags), diags)
+	}
+}
+
+func TestDiagnoseInvalidSource(t *testing.T) {
+	language := tree_sitter.NewLanguage(phpx.Language())
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(language)
+
+	sourceCode := []byte("<?php function( {")
+	tree := parser.Parse(sourceCode, nil)
+	if tree == nil {
+		t.Fatalf("failed to parse source under test")
+	}
+	if !tree.RootNode().HasError() {
+		t.Fatalf("expected source under test to be invalid")
+	}
+
+	diags := tree_sitter_phpx.Diagnose(tree, sourceCode)
+	if len(diags) == 0 {
+		t.Errorf("Diagnose on invalid source returned no diagnostics")
+	}
+}
+
+func TestParseWithRecovery(t *testing.T) {
+	language := tree_sitter.NewLanguage(phpx.Language())
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(language)
+
+	sourceCode := []byte("<?php function( { echo 'still here';")
+	tree, diags := tree_sitter_phpx.ParseWithRecovery(parser, sourceCode)
+	if tree == nil {
+		t.Fatalf("ParseWithRecovery returned nil tree")
+	}
+	if len(diags) == 0 {
+		t.Errorf("ParseWithRecovery on invalid source returned no diagnostics")
+	}
+}
+
+// TestParseWithRecoveryResynchronizes checks that the returned tree is a
+// genuinely recovered reparse, not just the first parse returned unchanged:
+// blanking out the broken declaration up to its statement boundary and
+// reparsing must leave strictly fewer diagnostics than the original parse.
+func TestParseWithRecoveryResynchronizes(t *testing.T) {
+	language := tree_sitter.NewLanguage(phpx.Language())
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(language)
+
+	sourceCode := []byte("<?php function(; echo 'still here';")
+
+	baseline := parser.Parse(sourceCode, nil)
+	if baseline == nil {
+		t.Fatalf("failed to parse source under test")
+	}
+	before := tree_sitter_phpx.Diagnose(baseline, sourceCode)
+	if len(before) == 0 {
+		t.Fatalf("expected source under test to be invalid")
+	}
+
+	tree, _ := tree_sitter_phpx.ParseWithRecovery(parser, sourceCode)
+	if tree == nil {
+		t.Fatalf("ParseWithRecovery returned nil tree")
+	}
+	after := tree_sitter_phpx.Diagnose(tree, sourceCode)
+
+	if len(after) >= len(before) {
+		t.Errorf("recovered tree has %d diagnostics, want fewer than the %d from the original parse (ParseWithRecovery should actually resynchronize, not just return the first parse)", len(after), len(before))
+	}
+}
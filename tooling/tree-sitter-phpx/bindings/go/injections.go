@@ -0,0 +1,156 @@
+package tree_sitter_phpx
+
+import (
+	"fmt"
+	"unsafe"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// InjectionRule describes one embedded-language injection that PHPX's
+// template markup requires: a node of NodeType should be reparsed with
+// Language, and ContentCapture is the `@injection.content` capture name in
+// queries/injections.scm that delimits the embedded text.
+//
+// RequireAttrName mirrors a `(#eq? @_name "...")` predicate in
+// injections.scm: when set, NodeType only matches as the `value` field of an
+// enclosing phpx_attribute whose `name` field equals RequireAttrName, rather
+// than every node of NodeType.
+type InjectionRule struct {
+	Language       string
+	NodeType       string
+	ContentCapture string
+
+	RequireAttrName string
+}
+
+// PHPXInjections returns the injection rules a host needs in order to
+// combine the phpx grammar with tree-sitter-html, tree-sitter-css, and
+// tree-sitter-javascript, one per HTML/CSS/JS injection in
+// queries/injections.scm. MultiLanguageParser applies them to the matched
+// node's whole text: a phpx_expression is reparsed including its
+// delimiters rather than only its inner expression as the query captures,
+// each phpx_expression is parsed separately rather than combined, and
+// phpx_raw_text matches wherever it appears, not only inside
+// phpx_script_element.
+func PHPXInjections() []InjectionRule {
+	return []InjectionRule{
+		{Language: "html", NodeType: "phpx_text", ContentCapture: "injection.content"},
+		{Language: "css", NodeType: "phpx_attribute_value", ContentCapture: "injection.content", RequireAttrName: "style"},
+		{Language: "javascript", NodeType: "phpx_raw_text", ContentCapture: "injection.content"},
+		{Language: "javascript", NodeType: "phpx_expression", ContentCapture: "injection.content"},
+	}
+}
+
+// MultiLanguageParser parses PHPX source and, for every node matched by
+// PHPXInjections, reparses its content with the corresponding embedded
+// language, so callers don't each have to reimplement the injection walk
+// themselves.
+type MultiLanguageParser struct {
+	languages map[string]*tree_sitter.Language
+	rules     []InjectionRule
+}
+
+// NewMultiLanguageParser builds a parser from a PHPX grammar (from
+// phpx.Language() or phpx_only.Language()) plus a map of injected language name to
+// the unsafe.Pointer each language's bindings expose (e.g.
+// tree_sitter_html.Language()).
+func NewMultiLanguageParser(phpx unsafe.Pointer, languages map[string]unsafe.Pointer) *MultiLanguageParser {
+	mp := &MultiLanguageParser{
+		languages: map[string]*tree_sitter.Language{"phpx": tree_sitter.NewLanguage(phpx)},
+		rules:     PHPXInjections(),
+	}
+	for name, ptr := range languages {
+		mp.languages[name] = tree_sitter.NewLanguage(ptr)
+	}
+	return mp
+}
+
+// Tree is a parsed PHPX tree plus every embedded-language subtree found via
+// injection, keyed by the injected language name.
+type Tree struct {
+	PHPX     *tree_sitter.Tree
+	Injected map[string][]*InjectedTree
+}
+
+// InjectedTree is one embedded-language subtree, still anchored to the
+// PHPX node it was parsed out of.
+type InjectedTree struct {
+	Host     *tree_sitter.Node
+	Language string
+	Tree     *tree_sitter.Tree
+}
+
+// Parse parses src as PHPX, then walks the result applying every rule from
+// PHPXInjections to produce embedded-language subtrees.
+func (mp *MultiLanguageParser) Parse(src []byte) (*Tree, error) {
+	phpxLang, ok := mp.languages["phpx"]
+	if !ok {
+		return nil, fmt.Errorf("phpx: multi-language parser missing base PHPX language")
+	}
+
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(phpxLang)
+
+	root := parser.Parse(src, nil)
+	if root == nil {
+		return nil, fmt.Errorf("phpx: failed to parse source")
+	}
+
+	result := &Tree{PHPX: root, Injected: map[string][]*InjectedTree{}}
+	mp.collectInjections(root.RootNode(), src, result)
+	return result, nil
+}
+
+func (mp *MultiLanguageParser) collectInjections(n *tree_sitter.Node, src []byte, result *Tree) {
+	for _, rule := range mp.rules {
+		if rule.RequireAttrName != "" {
+			// The attribute-name condition is checked on the phpx_attribute
+			// itself (it's the only node with both the name and value
+			// fields), then applied to its value field, which is the
+			// node of rule.NodeType that actually gets reparsed.
+			if n.Kind() != "phpx_attribute" {
+				continue
+			}
+			name := n.ChildByFieldName("name")
+			value := n.ChildByFieldName("value")
+			if name == nil || value == nil || value.Kind() != rule.NodeType {
+				continue
+			}
+			if string(src[name.StartByte():name.EndByte()]) != rule.RequireAttrName {
+				continue
+			}
+			mp.injectNode(value, rule, src, result)
+			continue
+		}
+		if n.Kind() != rule.NodeType {
+			continue
+		}
+		mp.injectNode(n, rule, src, result)
+	}
+
+	for i := uint(0); i < n.ChildCount(); i++ {
+		mp.collectInjections(n.Child(i), src, result)
+	}
+}
+
+func (mp *MultiLanguageParser) injectNode(n *tree_sitter.Node, rule InjectionRule, src []byte, result *Tree) {
+	lang, ok := mp.languages[rule.Language]
+	if !ok {
+		return
+	}
+	sub := tree_sitter.NewParser()
+	sub.SetLanguage(lang)
+	content := src[n.StartByte():n.EndByte()]
+	tree := sub.Parse(content, nil)
+	sub.Close()
+	if tree == nil {
+		return
+	}
+	result.Injected[rule.Language] = append(result.Injected[rule.Language], &InjectedTree{
+		Host:     n,
+		Language: rule.Language,
+		Tree:     tree,
+	})
+}
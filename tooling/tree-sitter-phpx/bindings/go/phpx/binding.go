@@ -0,0 +1,40 @@
+// Package phpx binds the PHPX grammar (PHP plus JSX-like component markup)
+// on its own, so callers that only need this grammar aren't forced to
+// compile the phpx_only scanner as well.
+package phpx
+
+// #cgo CFLAGS: -I../../../php/src -std=c11 -fPIC
+// #include "../../../php/src/parser.c"
+// #include "../../../php/src/scanner.c"
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// pinnedCLIVersion is the tree-sitter CLI version parser.c/scanner.c were
+// generated with; bump it alongside regenerating the grammar.
+const pinnedCLIVersion = "0.22.5"
+
+// minABIVersion/maxABIVersion bound the ABI-15 layout generated by
+// pinnedCLIVersion, matching the tree-sitter/go-tree-sitter v0.22+ runtime
+// this module uses (see LANGUAGE_VERSION in tree-sitter's API.h).
+const (
+	minABIVersion = 13
+	maxABIVersion = 15
+)
+
+func init() {
+	v := uint32(C.ts_language_version(C.tree_sitter_phpx()))
+	if v < minABIVersion || v > maxABIVersion {
+		panic(fmt.Sprintf(
+			"tree_sitter_phpx/phpx: generated parser reports ABI %d, outside the supported range [%d, %d] for tree-sitter CLI %s; regenerate the grammar",
+			v, minABIVersion, maxABIVersion, pinnedCLIVersion))
+	}
+}
+
+// Language returns the tree-sitter Language for the PHPX grammar.
+func Language() unsafe.Pointer {
+	return unsafe.Pointer(C.tree_sitter_phpx())
+}
@@ -0,0 +1,25 @@
+package phpx_test
+
+import (
+	"testing"
+
+	"github.com/samifouad/deka/tooling/tree-sitter-phpx/bindings/go/phpx"
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+func TestPHPGrammar(t *testing.T) {
+	language := tree_sitter.NewLanguage(phpx.Language())
+	if language == nil {
+		t.Errorf("Error loading PHPX grammar")
+	}
+
+	sourceCode := []byte("<?php echo 'Hello, World!';")
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(language)
+
+	tree := parser.Parse(sourceCode, nil)
+	if tree == nil || tree.RootNode().HasError() {
+		t.Errorf("Error parsing PHP")
+	}
+}
@@ -0,0 +1,40 @@
+// Package phpx_only binds the PHPX-only grammar (plain PHP, no component
+// markup) on its own, so callers that only need this grammar aren't forced
+// to compile the full phpx scanner as well.
+package phpx_only
+
+// #cgo CFLAGS: -I../../../php_only/src -std=c11 -fPIC
+// #include "../../../php_only/src/parser.c"
+// #include "../../../php_only/src/scanner.c"
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// pinnedCLIVersion is the tree-sitter CLI version parser.c/scanner.c were
+// generated with; bump it alongside regenerating the grammar.
+const pinnedCLIVersion = "0.22.5"
+
+// minABIVersion/maxABIVersion bound the ABI-15 layout generated by
+// pinnedCLIVersion, matching the tree-sitter/go-tree-sitter v0.22+ runtime
+// this module uses (see LANGUAGE_VERSION in tree-sitter's API.h).
+const (
+	minABIVersion = 13
+	maxABIVersion = 15
+)
+
+func init() {
+	v := uint32(C.ts_language_version(C.tree_sitter_phpx_only()))
+	if v < minABIVersion || v > maxABIVersion {
+		panic(fmt.Sprintf(
+			"tree_sitter_phpx/phpx_only: generated parser reports ABI %d, outside the supported range [%d, %d] for tree-sitter CLI %s; regenerate the grammar",
+			v, minABIVersion, maxABIVersion, pinnedCLIVersion))
+	}
+}
+
+// Language returns the tree-sitter Language for the PHPX-only grammar.
+func Language() unsafe.Pointer {
+	return unsafe.Pointer(C.tree_sitter_phpx_only())
+}
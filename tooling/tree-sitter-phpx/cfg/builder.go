@@ -0,0 +1,448 @@
+package cfg
+
+import tree_sitter "github.com/tree-sitter/go-tree-sitter"
+
+// stmt appends n's control flow starting from cur, returning the block
+// execution falls through to afterward, or nil if n always transfers
+// control elsewhere (return/throw/break/continue/goto).
+func (b *builder) stmt(cur *Block, n *tree_sitter.Node) *Block {
+	if cur == nil || n == nil {
+		return cur
+	}
+
+	switch n.Kind() {
+	case "compound_statement":
+		for i := uint(0); i < n.ChildCount(); i++ {
+			child := n.Child(i)
+			if child.Kind() == "{" || child.Kind() == "}" {
+				continue
+			}
+			cur = b.stmt(cur, child)
+			if cur == nil {
+				return nil
+			}
+		}
+		return cur
+
+	case "if_statement":
+		return b.ifStmt(cur, n)
+
+	case "while_statement":
+		return b.whileStmt(cur, n)
+
+	case "do_statement":
+		return b.doStmt(cur, n)
+
+	case "for_statement":
+		return b.forStmt(cur, n)
+
+	case "foreach_statement":
+		return b.foreachStmt(cur, n)
+
+	case "switch_statement":
+		return b.switchStmt(cur, n)
+
+	case "try_statement":
+		return b.tryStmt(cur, n)
+
+	case "break_statement":
+		target := b.findLoop(breakLevel(n, b.src))
+		if target != nil {
+			if out := b.runFinallies(cur, target.triesAtEntry); out != nil {
+				b.graph.addEdge(out, target.breakTo, EdgeNormal)
+			}
+		}
+		return nil
+
+	case "continue_statement":
+		target := b.findLoop(breakLevel(n, b.src))
+		if target != nil {
+			if out := b.runFinallies(cur, target.triesAtEntry); out != nil {
+				b.graph.addEdge(out, target.continueTo, EdgeNormal)
+			}
+		}
+		return nil
+
+	case "return_statement":
+		cur.Stmts = append(cur.Stmts, n)
+		if out := b.runFinallies(cur, 0); out != nil {
+			b.graph.addEdge(out, b.graph.Exit, EdgeNormal)
+		}
+		return nil
+
+	case "throw_statement":
+		cur.Stmts = append(cur.Stmts, n)
+		b.raiseException(cur)
+		return nil
+
+	case "goto_statement":
+		cur.Stmts = append(cur.Stmts, n)
+		if target, ok := b.labels[labelOf(n, b.src)]; ok {
+			b.graph.addEdge(cur, target, EdgeNormal)
+		}
+		return nil
+
+	case "named_label_statement":
+		// A label starts a new block so gotos/fallthrough can target it.
+		next := b.graph.newBlock()
+		b.graph.addEdge(cur, next, EdgeNormal)
+		b.labels[labelOf(n, b.src)] = next
+		if stmt := n.ChildByFieldName("statement"); stmt != nil {
+			return b.stmt(next, stmt)
+		}
+		return next
+
+	default:
+		// Straight-line statement (expression, declaration, ...): may
+		// itself contain a throwing call, which only matters inside a
+		// try body (handled by tryStmt via raiseException per-statement).
+		cur.Stmts = append(cur.Stmts, n)
+		if len(b.tries) > 0 {
+			b.raiseExceptionKeepLive(cur)
+		}
+		return cur
+	}
+}
+
+func (b *builder) ifStmt(cur *Block, n *tree_sitter.Node) *Block {
+	cond := n.ChildByFieldName("condition")
+	if cond != nil {
+		cur.Stmts = append(cur.Stmts, cond)
+	}
+
+	join := b.graph.newBlock()
+
+	thenBlock := b.graph.newBlock()
+	b.graph.addEdge(cur, thenBlock, EdgeTrue)
+	if out := b.stmt(thenBlock, n.ChildByFieldName("body")); out != nil {
+		b.graph.addEdge(out, join, EdgeNormal)
+	}
+
+	elseClause := n.ChildByFieldName("alternative")
+	if elseClause != nil && elseClause.Kind() == "else_clause" {
+		if body := elseClause.ChildByFieldName("body"); body != nil {
+			elseClause = body
+		}
+	}
+	if elseClause != nil {
+		elseBlock := b.graph.newBlock()
+		b.graph.addEdge(cur, elseBlock, EdgeFalse)
+		if out := b.stmt(elseBlock, elseClause); out != nil {
+			b.graph.addEdge(out, join, EdgeNormal)
+		}
+	} else {
+		b.graph.addEdge(cur, join, EdgeFalse)
+	}
+
+	if len(join.Preds) == 0 {
+		return nil // every branch transfers control elsewhere
+	}
+	return join
+}
+
+func (b *builder) whileStmt(cur *Block, n *tree_sitter.Node) *Block {
+	head := b.graph.newBlock()
+	b.graph.addEdge(cur, head, EdgeNormal)
+
+	after := b.graph.newBlock()
+	body := b.graph.newBlock()
+	b.graph.addEdge(head, body, EdgeTrue)
+	b.graph.addEdge(head, after, EdgeFalse)
+
+	b.pushLoop(after, head)
+	if out := b.stmt(body, n.ChildByFieldName("body")); out != nil {
+		b.graph.addEdge(out, head, EdgeNormal)
+	}
+	b.popLoop()
+
+	return after
+}
+
+func (b *builder) doStmt(cur *Block, n *tree_sitter.Node) *Block {
+	body := b.graph.newBlock()
+	b.graph.addEdge(cur, body, EdgeNormal)
+
+	cond := b.graph.newBlock()
+	after := b.graph.newBlock()
+
+	b.pushLoop(after, cond)
+	if out := b.stmt(body, n.ChildByFieldName("body")); out != nil {
+		b.graph.addEdge(out, cond, EdgeNormal)
+	}
+	b.popLoop()
+
+	b.graph.addEdge(cond, body, EdgeTrue)
+	b.graph.addEdge(cond, after, EdgeFalse)
+	return after
+}
+
+func (b *builder) forStmt(cur *Block, n *tree_sitter.Node) *Block {
+	head := b.graph.newBlock()
+	b.graph.addEdge(cur, head, EdgeNormal)
+
+	body := b.graph.newBlock()
+	update := b.graph.newBlock()
+	after := b.graph.newBlock()
+
+	b.graph.addEdge(head, body, EdgeTrue)
+	b.graph.addEdge(head, after, EdgeFalse)
+
+	b.pushLoop(after, update)
+	if out := b.stmt(body, n.ChildByFieldName("body")); out != nil {
+		b.graph.addEdge(out, update, EdgeNormal)
+	}
+	b.popLoop()
+
+	b.graph.addEdge(update, head, EdgeNormal)
+	return after
+}
+
+func (b *builder) foreachStmt(cur *Block, n *tree_sitter.Node) *Block {
+	head := b.graph.newBlock()
+	b.graph.addEdge(cur, head, EdgeNormal)
+
+	body := b.graph.newBlock()
+	after := b.graph.newBlock()
+	b.graph.addEdge(head, body, EdgeTrue)
+	b.graph.addEdge(head, after, EdgeFalse)
+
+	b.pushLoop(after, head)
+	if out := b.stmt(body, n.ChildByFieldName("body")); out != nil {
+		b.graph.addEdge(out, head, EdgeNormal)
+	}
+	b.popLoop()
+
+	return after
+}
+
+// switchStmt models `switch` and `match` arms identically: each arm is its
+// own block and falls through to the next arm's block unless it
+// terminates (break/return/throw), matching PHP `switch` fall-through
+// semantics. `match` arms never fall through in source, but the CFG shape
+// is the same since each arm already ends in an implicit break.
+func (b *builder) switchStmt(cur *Block, n *tree_sitter.Node) *Block {
+	subject := n.ChildByFieldName("condition")
+	if subject != nil {
+		cur.Stmts = append(cur.Stmts, subject)
+	}
+
+	after := b.graph.newBlock()
+	b.pushLoop(after, after) // `continue` in a switch behaves like `break`
+
+	body := n.ChildByFieldName("body")
+	var prevArm *Block
+	if body != nil {
+		for i := uint(0); i < body.ChildCount(); i++ {
+			arm := body.Child(i)
+			if arm.Kind() != "case_statement" && arm.Kind() != "default_statement" {
+				continue
+			}
+			armBlock := b.graph.newBlock()
+			b.graph.addEdge(cur, armBlock, EdgeNormal)
+			if prevArm != nil {
+				b.graph.addEdge(prevArm, armBlock, EdgeNormal) // fall-through
+			}
+
+			out := armBlock
+			for j := uint(0); j < arm.ChildCount(); j++ {
+				stmtNode := arm.Child(j)
+				if out == nil {
+					break
+				}
+				out = b.stmt(out, stmtNode)
+			}
+			prevArm = out
+		}
+	}
+	if prevArm != nil {
+		b.graph.addEdge(prevArm, after, EdgeNormal)
+	}
+
+	b.popLoop()
+	return after
+}
+
+// tryStmt builds the CFG for a try/catch/finally. A finally clause must run
+// on every way out of the try/catch — normal completion, a caught exception,
+// or an early exit (return/throw/break/continue) — so it is lowered once per
+// exit path rather than shared: the normal-completion paths are threaded
+// through it below via joinThroughFinally, and early exits are threaded
+// through it by runFinallies/raiseException, which consult b.tries while the
+// try body and catch bodies are being built.
+func (b *builder) tryStmt(cur *Block, n *tree_sitter.Node) *Block {
+	after := b.graph.newBlock()
+
+	var catchEntries []*Block
+	var catchClauses []*tree_sitter.Node
+	for i := uint(0); i < n.ChildCount(); i++ {
+		if c := n.Child(i); c.Kind() == "catch_clause" {
+			catchClauses = append(catchClauses, c)
+			catchEntries = append(catchEntries, b.graph.newBlock())
+		}
+	}
+
+	var finallyBody *tree_sitter.Node
+	for i := uint(0); i < n.ChildCount(); i++ {
+		if c := n.Child(i); c.Kind() == "finally_clause" {
+			finallyBody = c.ChildByFieldName("body")
+		}
+	}
+
+	b.tries = append(b.tries, tryScope{catchers: catchEntries, finally: finallyBody})
+	tryOut := b.stmt(cur, n.ChildByFieldName("body"))
+	b.tries = b.tries[:len(b.tries)-1]
+	b.joinThroughFinally(tryOut, finallyBody, after)
+
+	for i, clause := range catchClauses {
+		b.graph.addEdge(cur, catchEntries[i], EdgeException)
+		// A throw inside a catch body isn't caught by the same clause, but
+		// still has to run this try's finally, so only finally (no
+		// catchers) is active while lowering it.
+		b.tries = append(b.tries, tryScope{finally: finallyBody})
+		catchOut := b.stmt(catchEntries[i], clause.ChildByFieldName("body"))
+		b.tries = b.tries[:len(b.tries)-1]
+		b.joinThroughFinally(catchOut, finallyBody, after)
+	}
+
+	if len(after.Preds) == 0 {
+		return nil // neither the try body nor any catch completes normally
+	}
+	return after
+}
+
+// joinThroughFinally wires a try/catch body's normal-completion exit (out,
+// nil if it never completes normally) through finallyBody, if any, into
+// after.
+func (b *builder) joinThroughFinally(out *Block, finallyBody *tree_sitter.Node, after *Block) {
+	if out == nil {
+		return
+	}
+	if finallyBody == nil {
+		b.graph.addEdge(out, after, EdgeNormal)
+		return
+	}
+	entry := b.graph.newBlock()
+	b.graph.addEdge(out, entry, EdgeNormal)
+	if finOut := b.stmt(entry, finallyBody); finOut != nil {
+		b.graph.addEdge(finOut, after, EdgeNormal)
+	}
+}
+
+// raiseException wires an edge from a throw statement to every matching
+// catch of every enclosing try, innermost first, threading the escape path
+// through each try's finally (if any) before it reaches the next try out —
+// the same duplication-per-exit-path runFinallies uses for
+// return/break/continue. It never returns a live successor (the throw
+// itself never falls through).
+func (b *builder) raiseException(cur *Block) {
+	escape := cur
+	for i := len(b.tries) - 1; i >= 0; i-- {
+		scope := b.tries[i]
+		for _, entry := range scope.catchers {
+			b.graph.addEdge(escape, entry, EdgeException)
+		}
+		if scope.finally == nil {
+			continue
+		}
+		out := b.lowerFinallyBody(escape, EdgeException, i, scope.finally)
+		if out == nil {
+			return // the finally itself never completes normally
+		}
+		escape = out
+	}
+}
+
+// runFinallies threads control from `from` through the finally bodies of
+// b.tries[fromDepth:], innermost first, so an early exit (return/break/
+// continue) still runs the cleanup of every try scope it passes through on
+// its way out. fromDepth is 0 for return (exits every open try) or a
+// loopTarget's triesAtEntry for break/continue (exits only the try scopes
+// opened since that loop started). Returns the block execution continues
+// from, or nil if a finally itself never completes normally (e.g. it
+// returns), in which case the caller must not wire any further edge.
+func (b *builder) runFinallies(from *Block, fromDepth int) *Block {
+	cur := from
+	for i := len(b.tries) - 1; i >= fromDepth; i-- {
+		if b.tries[i].finally == nil {
+			continue
+		}
+		out := b.lowerFinallyBody(cur, EdgeNormal, i, b.tries[i].finally)
+		if out == nil {
+			return nil
+		}
+		cur = out
+	}
+	return cur
+}
+
+// lowerFinallyBody wires entryKind from `from` into a fresh block and lowers
+// scope i's finally body there, with b.tries temporarily truncated to the
+// scopes outside scope i. A finally body isn't covered by its own try's
+// catch (so it must not be re-routed through its own finally again if one
+// of its own statements throws or returns), but is still covered by any try
+// it's nested inside, hence only hiding i and everything above it rather
+// than clearing b.tries entirely.
+func (b *builder) lowerFinallyBody(from *Block, entryKind EdgeKind, i int, finallyBody *tree_sitter.Node) *Block {
+	entry := b.graph.newBlock()
+	b.graph.addEdge(from, entry, entryKind)
+	saved := b.tries
+	b.tries = b.tries[:i]
+	out := b.stmt(entry, finallyBody)
+	b.tries = saved
+	return out
+}
+
+// raiseExceptionKeepLive is used for ordinary statements that may contain a
+// throwing call: it adds the same exceptional edges as raiseException but
+// the block remains live for its normal, non-throwing continuation.
+func (b *builder) raiseExceptionKeepLive(cur *Block) {
+	b.raiseException(cur)
+}
+
+func (b *builder) pushLoop(breakTo, continueTo *Block) {
+	b.loops = append(b.loops, loopTarget{breakTo: breakTo, continueTo: continueTo, triesAtEntry: len(b.tries)})
+}
+
+func (b *builder) popLoop() {
+	b.loops = b.loops[:len(b.loops)-1]
+}
+
+// findLoop returns the loop `level` loops out from the innermost one (level
+// 1 is the innermost, matching PHP's `break`/`continue` with no argument),
+// or nil if there aren't that many loops open.
+func (b *builder) findLoop(level int) *loopTarget {
+	idx := len(b.loops) - level
+	if idx < 0 || idx >= len(b.loops) {
+		return nil
+	}
+	return &b.loops[idx]
+}
+
+// breakLevel reads the integer nesting level off a break_statement or
+// continue_statement (`break 2;`), defaulting to 1 (`break;`) when absent.
+// This is PHP's own semantics: unlike goto, break/continue never take a
+// string label.
+func breakLevel(n *tree_sitter.Node, src []byte) int {
+	l := n.ChildByFieldName("level")
+	if l == nil {
+		return 1
+	}
+	level := 0
+	for _, c := range src[l.StartByte():l.EndByte()] {
+		if c < '0' || c > '9' {
+			return 1
+		}
+		level = level*10 + int(c-'0')
+	}
+	if level <= 0 {
+		return 1
+	}
+	return level
+}
+
+func labelOf(n *tree_sitter.Node, src []byte) string {
+	if l := n.ChildByFieldName("label"); l != nil {
+		return string(src[l.StartByte():l.EndByte()])
+	}
+	return ""
+}
@@ -0,0 +1,126 @@
+// Package cfg builds Control Flow Graphs over a function/method body parsed
+// by tree_sitter_phpx, for use as a static-analysis backend.
+package cfg
+
+import (
+	"fmt"
+
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// EdgeKind distinguishes normal fall-through/branch edges from the
+// exceptional edges that run from a throwing call to its matching catch.
+type EdgeKind int
+
+const (
+	EdgeNormal    EdgeKind = iota
+	EdgeTrue               // taken branch of a condition
+	EdgeFalse              // not-taken branch of a condition
+	EdgeException          // try body -> catch
+)
+
+// Block is a basic block: a maximal run of straight-line statements with a
+// single entry and single exit.
+type Block struct {
+	ID    int
+	Stmts []*tree_sitter.Node
+
+	Preds []*Edge
+	Succs []*Edge
+}
+
+// Edge connects two blocks.
+type Edge struct {
+	From, To *Block
+	Kind     EdgeKind
+}
+
+// Graph is the CFG for a single function/method body.
+type Graph struct {
+	Entry  *Block
+	Exit   *Block
+	Blocks []*Block
+}
+
+func (g *Graph) newBlock() *Block {
+	b := &Block{ID: len(g.Blocks)}
+	g.Blocks = append(g.Blocks, b)
+	return b
+}
+
+func (g *Graph) addEdge(from, to *Block, kind EdgeKind) {
+	if from == nil || to == nil {
+		return
+	}
+	e := &Edge{From: from, To: to, Kind: kind}
+	from.Succs = append(from.Succs, e)
+	to.Preds = append(to.Preds, e)
+}
+
+// loopTarget holds the blocks `break`/`continue` should jump to for one
+// enclosing loop or switch/match. PHP's break/continue take an integer
+// nesting level (`break 2;`), not a string label — only `goto` uses string
+// labels (tracked separately via builder.labels) — so triesAtEntry is the
+// only other thing a jump to this target needs: how many of the currently
+// open try scopes (see tryScope) sit between the jump and this loop, and so
+// must run their finally before the jump completes.
+type loopTarget struct {
+	breakTo      *Block
+	continueTo   *Block
+	triesAtEntry int
+}
+
+// tryScope is one currently-open try statement: its catch entry blocks (if
+// any) and its finally body (if any). raiseException and runFinallies walk
+// builder.tries innermost-first to route throw/return/break/continue through
+// every enclosing finally they pass on the way out.
+type tryScope struct {
+	catchers []*Block
+	finally  *tree_sitter.Node
+}
+
+// builder threads the blocks being built plus the control-flow context
+// (enclosing loops and try/catch/finally) needed to wire
+// break/continue/return/throw.
+type builder struct {
+	src    []byte
+	graph  *Graph
+	loops  []loopTarget
+	tries  []tryScope
+	labels map[string]*Block
+}
+
+// BuildCFG builds the Control Flow Graph for the body of a function/method
+// node produced by the phpx/phpx_only grammar bindings. node must be a
+// function_definition or method_declaration (or any node exposing a `body`
+// field containing a compound_statement).
+func BuildCFG(node *tree_sitter.Node, src []byte) (*Graph, error) {
+	if node == nil {
+		return nil, fmt.Errorf("cfg: nil node")
+	}
+	body := node.ChildByFieldName("body")
+	if body == nil {
+		return nil, fmt.Errorf("cfg: node %q has no body", node.Kind())
+	}
+
+	g := &Graph{}
+	g.Entry = g.newBlock()
+	g.Exit = g.newBlock()
+
+	b := &builder{src: src, graph: g, labels: map[string]*Block{}}
+	cur := g.Entry
+	cur = b.stmt(cur, body)
+	b.addEdgeIfLive(cur, g.Exit, EdgeNormal)
+
+	return g, nil
+}
+
+// addEdgeIfLive wires from->to unless from is a block that already ends in
+// a terminating statement (return/throw/goto), which is tracked by the
+// caller passing nil for "already terminated".
+func (b *builder) addEdgeIfLive(from, to *Block, kind EdgeKind) {
+	if from == nil {
+		return
+	}
+	b.graph.addEdge(from, to, kind)
+}
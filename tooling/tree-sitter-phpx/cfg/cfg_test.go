@@ -0,0 +1,157 @@
+package cfg_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/samifouad/deka/tooling/tree-sitter-phpx/bindings/go/phpx"
+	"github.com/samifouad/deka/tooling/tree-sitter-phpx/cfg"
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+func TestBuildCFGIfElse(t *testing.T) {
+	language := tree_sitter.NewLanguage(phpx.Language())
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(language)
+
+	src := []byte(`<?php
+function f($x) {
+    if ($x) {
+        return 1;
+    } else {
+        return 2;
+    }
+}`)
+	tree := parser.Parse(src, nil)
+	if tree == nil || tree.RootNode().HasError() {
+		t.Fatalf("failed to parse source under test")
+	}
+
+	fn := findFunctionDefinition(tree.RootNode())
+	if fn == nil {
+		t.Fatalf("could not find function_definition node")
+	}
+
+	graph, err := cfg.BuildCFG(fn, src)
+	if err != nil {
+		t.Fatalf("BuildCFG: %v", err)
+	}
+	if graph.Entry == nil || graph.Exit == nil {
+		t.Fatalf("graph missing entry/exit")
+	}
+	if len(graph.Exit.Preds) != 2 {
+		t.Errorf("want 2 paths into exit (one per branch's return), got %d", len(graph.Exit.Preds))
+	}
+	if dot := graph.DOT(); !strings.Contains(dot, "digraph cfg") {
+		t.Errorf("DOT output missing graph header: %s", dot)
+	}
+}
+
+// TestBuildCFGBreakLevel checks that `break 2;` exits the outer loop
+// directly rather than falling back to the innermost one.
+func TestBuildCFGBreakLevel(t *testing.T) {
+	language := tree_sitter.NewLanguage(phpx.Language())
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(language)
+
+	src := []byte(`<?php
+function f($xs) {
+    foreach ($xs as $x) {
+        foreach ($x as $y) {
+            break 2;
+        }
+    }
+}`)
+	tree := parser.Parse(src, nil)
+	if tree == nil || tree.RootNode().HasError() {
+		t.Fatalf("failed to parse source under test")
+	}
+
+	fn := findFunctionDefinition(tree.RootNode())
+	if fn == nil {
+		t.Fatalf("could not find function_definition node")
+	}
+
+	graph, err := cfg.BuildCFG(fn, src)
+	if err != nil {
+		t.Fatalf("BuildCFG: %v", err)
+	}
+
+	// Rather than guess the outer loop's `after` block by ID, find it by
+	// walking from Exit: the function falls through the outer foreach's
+	// after-block straight into Exit, so Exit should have exactly one
+	// predecessor, and that block's only normal (non-false) predecessor is
+	// the `break 2;` jump from the inner loop's body.
+	if len(graph.Exit.Preds) != 1 {
+		t.Fatalf("want exactly 1 path into exit, got %d", len(graph.Exit.Preds))
+	}
+	outerAfter := graph.Exit.Preds[0].From
+	foundBreakEdge := false
+	for _, e := range outerAfter.Preds {
+		if e.Kind == cfg.EdgeNormal {
+			foundBreakEdge = true
+		}
+	}
+	if !foundBreakEdge {
+		t.Errorf("want `break 2;` to land directly on the outer loop's after-block, got preds %+v", outerAfter.Preds)
+	}
+}
+
+// TestBuildCFGTryFinallyOnReturn checks that a `return` inside a try body
+// still runs the try's finally block before reaching Exit, instead of
+// bypassing it.
+func TestBuildCFGTryFinallyOnReturn(t *testing.T) {
+	language := tree_sitter.NewLanguage(phpx.Language())
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(language)
+
+	src := []byte(`<?php
+function f() {
+    try {
+        return 1;
+    } finally {
+        cleanup();
+    }
+}`)
+	tree := parser.Parse(src, nil)
+	if tree == nil || tree.RootNode().HasError() {
+		t.Fatalf("failed to parse source under test")
+	}
+
+	fn := findFunctionDefinition(tree.RootNode())
+	if fn == nil {
+		t.Fatalf("could not find function_definition node")
+	}
+
+	graph, err := cfg.BuildCFG(fn, src)
+	if err != nil {
+		t.Fatalf("BuildCFG: %v", err)
+	}
+
+	foundFinallyStmt := false
+	for _, e := range graph.Exit.Preds {
+		for _, stmt := range e.From.Stmts {
+			if strings.Contains(string(src[stmt.StartByte():stmt.EndByte()]), "cleanup") {
+				foundFinallyStmt = true
+			}
+		}
+	}
+	if !foundFinallyStmt {
+		t.Errorf("want the block feeding Exit to contain the finally body's statements, got preds %+v", graph.Exit.Preds)
+	}
+}
+
+func findFunctionDefinition(n *tree_sitter.Node) *tree_sitter.Node {
+	if n.Kind() == "function_definition" {
+		return n
+	}
+	for i := uint(0); i < n.ChildCount(); i++ {
+		if found := findFunctionDefinition(n.Child(i)); found != nil {
+			return found
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package cfg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DOT renders g as a Graphviz `dot` graph for debugging.
+func (g *Graph) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph cfg {\n")
+	for _, b := range g.Blocks {
+		label := fmt.Sprintf("B%d (%d stmts)", b.ID, len(b.Stmts))
+		shape := "box"
+		switch b {
+		case g.Entry:
+			label = "entry"
+			shape = "oval"
+		case g.Exit:
+			label = "exit"
+			shape = "oval"
+		}
+		fmt.Fprintf(&sb, "  B%d [shape=%s, label=%q];\n", b.ID, shape, label)
+	}
+	for _, b := range g.Blocks {
+		for _, e := range b.Succs {
+			style := ""
+			switch e.Kind {
+			case EdgeTrue:
+				style = " [label=\"true\"]"
+			case EdgeFalse:
+				style = " [label=\"false\"]"
+			case EdgeException:
+				style = " [label=\"throw\", style=dashed]"
+			}
+			fmt.Fprintf(&sb, "  B%d -> B%d%s;\n", e.From.ID, e.To.ID, style)
+		}
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
@@ -0,0 +1,130 @@
+package trivia
+
+import (
+	"bytes"
+
+	"github.com/samifouad/deka/tooling/tree-sitter-phpx/ast"
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// Attach walks tree's comment nodes and blank-line whitespace runs and
+// associates each with the nearest node of file: trivia on the same line
+// as the end of the preceding node is that node's trailing trivia; trivia
+// is leading for the node it immediately precedes. Trivia next to no
+// node (an otherwise-empty block, or past the last declaration) becomes
+// dangling trivia on the nearest enclosing node.
+func Attach(tree *tree_sitter.Tree, src []byte, file *ast.File) *Map {
+	pieces := collectPieces(tree.RootNode(), src)
+	nodes := collectNodes(file)
+
+	m := newMap()
+	for _, p := range pieces {
+		before, after, enclosing := surroundingNodes(nodes, p)
+		switch {
+		case before != nil && sameLine(src, before.Range().EndByte, p.Range.StartByte):
+			a := m.entry(before)
+			a.Trailing = append(a.Trailing, p)
+		case after != nil:
+			a := m.entry(after)
+			a.Leading = append(a.Leading, p)
+		case enclosing != nil:
+			a := m.entry(enclosing)
+			a.Dangling = append(a.Dangling, p)
+		default:
+			a := m.entry(file)
+			a.Dangling = append(a.Dangling, p)
+		}
+	}
+	return m
+}
+
+// collectPieces walks the raw CST for comment tokens and whitespace gaps
+// between sibling tokens that span a blank line, since ordinary
+// single-space/single-newline separators carry no information worth
+// preserving.
+func collectPieces(root *tree_sitter.Node, src []byte) []Piece {
+	var pieces []Piece
+	var prevEnd uint
+	var walk func(n *tree_sitter.Node)
+	walk = func(n *tree_sitter.Node) {
+		if n.ChildCount() == 0 {
+			if gap := src[prevEnd:n.StartByte()]; bytes.Count(gap, []byte("\n")) >= 2 {
+				pieces = append(pieces, Piece{
+					Kind:  KindWhitespace,
+					Text:  string(gap),
+					Range: ast.Range{StartByte: prevEnd, EndByte: n.StartByte()},
+				})
+			}
+			if n.Kind() == "comment" {
+				pieces = append(pieces, Piece{
+					Kind:  KindComment,
+					Text:  string(src[n.StartByte():n.EndByte()]),
+					Range: ast.Range{StartByte: n.StartByte(), EndByte: n.EndByte()},
+				})
+			}
+			prevEnd = n.EndByte()
+			return
+		}
+		for i := uint(0); i < n.ChildCount(); i++ {
+			walk(n.Child(i))
+		}
+	}
+	walk(root)
+	return pieces
+}
+
+// collectNodes flattens file's AST into source order via the Walker, so
+// trivia can be matched against it by byte range without every caller
+// reimplementing the traversal.
+func collectNodes(file *ast.File) []ast.Node {
+	var nodes []ast.Node
+	v := &collector{add: func(n ast.Node) { nodes = append(nodes, n) }}
+	ast.NewWalker(v).Walk(file)
+	return nodes
+}
+
+type collector struct {
+	ast.BaseVisitor
+	add func(ast.Node)
+}
+
+func (c *collector) Enter(n ast.Node) bool {
+	c.add(n)
+	return false
+}
+
+// surroundingNodes finds the innermost node whose range encloses p and,
+// among the nodes inside that one, the nearest node ending before p and the
+// nearest node starting after it.
+func surroundingNodes(nodes []ast.Node, p Piece) (before, after, enclosing ast.Node) {
+	for _, n := range nodes {
+		// Walker order is outermost first, so the last match is innermost.
+		if r := n.Range(); r.StartByte <= p.Range.StartByte && r.EndByte >= p.Range.EndByte {
+			enclosing = n
+		}
+	}
+	for _, n := range nodes {
+		r := n.Range()
+		if enclosing != nil && (n == enclosing || r.StartByte < enclosing.Range().StartByte || r.EndByte > enclosing.Range().EndByte) {
+			continue
+		}
+		if r.EndByte <= p.Range.StartByte {
+			if before == nil || r.EndByte > before.Range().EndByte {
+				before = n
+			}
+		}
+		if r.StartByte >= p.Range.EndByte {
+			if after == nil || r.StartByte < after.Range().StartByte {
+				after = n
+			}
+		}
+	}
+	return before, after, enclosing
+}
+
+func sameLine(src []byte, a, b uint) bool {
+	if b < a {
+		a, b = b, a
+	}
+	return !bytes.Contains(src[a:b], []byte("\n"))
+}
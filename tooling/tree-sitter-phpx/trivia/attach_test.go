@@ -0,0 +1,53 @@
+package trivia
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/samifouad/deka/tooling/tree-sitter-phpx/ast"
+	"github.com/samifouad/deka/tooling/tree-sitter-phpx/bindings/go/phpx"
+	tree_sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// TestAttachAndFormatRealSource runs Attach against a real parse (through
+// ast.Lower, not a hand-built node as in printer_test.go), so the trivia
+// attaches a real comment to a real Function node before Printer re-emits
+// it, exercising collectPieces/collectNodes/ast.Walker together instead of
+// only the Printer in isolation.
+func TestAttachAndFormatRealSource(t *testing.T) {
+	language := tree_sitter.NewLanguage(phpx.Language())
+	parser := tree_sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(language)
+
+	src := []byte("<?php\n// greet\nfunction greet() {\n    return 1; // trailing\n}\n")
+	tree := parser.Parse(src, nil)
+	if tree == nil || tree.RootNode().HasError() {
+		t.Fatalf("failed to parse source under test")
+	}
+
+	file := ast.Lower(tree, src)
+	var fn *ast.Function
+	for _, d := range file.Decls {
+		if f, ok := d.(*ast.Function); ok {
+			fn = f
+		}
+	}
+	if fn == nil {
+		t.Fatalf("Lower did not produce the greet() function")
+	}
+
+	m := Attach(tree, src, file)
+
+	leading := WithLeadingTrivia(m, fn)
+	if len(leading) != 1 || !strings.Contains(leading[0].Text, "greet") {
+		t.Fatalf("want the function's leading trivia to be the preceding comment, got %+v", leading)
+	}
+
+	commentStart := leading[0].Range.StartByte
+	want := string(src[commentStart:fn.Range().EndByte])
+	got := string(Format(fn, src, m, Options{}))
+	if got != want {
+		t.Errorf("Format round-trip over real source = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,78 @@
+package trivia
+
+import (
+	"bytes"
+
+	"github.com/samifouad/deka/tooling/tree-sitter-phpx/ast"
+)
+
+// Options controls Printer/Format output.
+type Options struct {
+	// IncludeDangling appends a node's dangling trivia (e.g. a trailing
+	// comment just before a block's closing brace) after its own text.
+	IncludeDangling bool
+}
+
+// Printer re-emits AST nodes as source text, restoring the leading and
+// trailing trivia recorded in a Map so the output matches the original
+// source byte-for-byte for any node whose Range came from a real parse.
+type Printer struct {
+	Src     []byte
+	Trivia  *Map
+	Options Options
+}
+
+// NewPrinter returns a Printer that prints against src using the trivia
+// recorded in m.
+func NewPrinter(src []byte, m *Map, opts Options) *Printer {
+	return &Printer{Src: src, Trivia: m, Options: opts}
+}
+
+// Print renders n, including its recorded leading/trailing (and, if
+// Options.IncludeDangling is set, dangling) trivia.
+func (p *Printer) Print(n ast.Node) []byte {
+	var buf bytes.Buffer
+	r := n.Range()
+	leading := WithLeadingTrivia(p.Trivia, n)
+	for i, l := range leading {
+		buf.WriteString(l.Text)
+		// Re-emit the source between this piece and the next one (or the
+		// node), e.g. the newline after a line comment.
+		next := r.StartByte
+		if i+1 < len(leading) {
+			next = leading[i+1].Range.StartByte
+		}
+		if l.Range.EndByte < next && next <= uint(len(p.Src)) {
+			buf.Write(p.Src[l.Range.EndByte:next])
+		}
+	}
+
+	if r.EndByte > r.StartByte {
+		buf.Write(p.Src[r.StartByte:r.EndByte])
+	}
+
+	prev := r.EndByte
+	for _, trailing := range WithTrailingTrivia(p.Trivia, n) {
+		if prev < trailing.Range.StartByte && trailing.Range.StartByte <= uint(len(p.Src)) {
+			buf.Write(p.Src[prev:trailing.Range.StartByte])
+		}
+		buf.WriteString(trailing.Text)
+		prev = trailing.Range.EndByte
+	}
+	if p.Options.IncludeDangling {
+		if a, ok := p.Trivia.byNode[n]; ok {
+			for _, d := range a.Dangling {
+				buf.WriteString(d.Text)
+			}
+		}
+	}
+	return buf.Bytes()
+}
+
+// Format re-emits n as source text using the trivia recorded in m. It is
+// the entry point most callers want; build m once per file with Attach and
+// reuse it across Format calls, e.g. after a refactor moves n elsewhere in
+// the tree.
+func Format(n ast.Node, src []byte, m *Map, opts Options) []byte {
+	return NewPrinter(src, m, opts).Print(n)
+}
@@ -0,0 +1,23 @@
+package trivia
+
+import (
+	"testing"
+
+	"github.com/samifouad/deka/tooling/tree-sitter-phpx/ast"
+)
+
+func TestPrinterRoundTripsTrivia(t *testing.T) {
+	src := []byte("// leading\nconst X\n// trailing")
+	node := &ast.Const{Name: "X"}
+	node.R = ast.Range{StartByte: 11, EndByte: 18} // "const X"
+
+	m := newMap()
+	m.entry(node).Leading = []Piece{{Kind: KindComment, Text: "// leading\n", Range: ast.Range{StartByte: 0, EndByte: 11}}}
+	m.entry(node).Trailing = []Piece{{Kind: KindComment, Text: "\n// trailing", Range: ast.Range{StartByte: 18, EndByte: 30}}}
+
+	got := string(Format(node, src, m, Options{}))
+	want := string(src)
+	if got != want {
+		t.Errorf("Format round-trip = %q, want %q", got, want)
+	}
+}
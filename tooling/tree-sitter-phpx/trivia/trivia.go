@@ -0,0 +1,69 @@
+// Package trivia attaches free-floating comments and significant
+// whitespace runs (both dropped by tree-sitter as "extras" with no stable
+// attachment) to the nearest AST node, Roslyn-style, so a Printer can
+// re-emit the original source byte-for-byte from the AST plus trivia.
+package trivia
+
+import "github.com/samifouad/deka/tooling/tree-sitter-phpx/ast"
+
+// Kind distinguishes a comment from a run of whitespace spanning a blank
+// line, the only whitespace Attach records for round-tripping.
+type Kind int
+
+const (
+	KindComment Kind = iota
+	KindWhitespace
+)
+
+// Piece is one run of trivia: a single comment, or one contiguous
+// whitespace run.
+type Piece struct {
+	Kind  Kind
+	Text  string
+	Range ast.Range
+}
+
+// Attachment holds the trivia associated with one AST node: Leading
+// precedes the node (its own line or earlier), Trailing follows it on the
+// same line, and Dangling is trivia that belongs to the node's span (e.g.
+// inside an otherwise-empty block) but attaches to neither a leading nor a
+// trailing neighbor.
+type Attachment struct {
+	Leading  []Piece
+	Trailing []Piece
+	Dangling []Piece
+}
+
+// Map holds every node's Attachment, built by Attach.
+type Map struct {
+	byNode map[ast.Node]*Attachment
+}
+
+func newMap() *Map {
+	return &Map{byNode: map[ast.Node]*Attachment{}}
+}
+
+func (m *Map) entry(n ast.Node) *Attachment {
+	a, ok := m.byNode[n]
+	if !ok {
+		a = &Attachment{}
+		m.byNode[n] = a
+	}
+	return a
+}
+
+// WithLeadingTrivia returns n's leading trivia (empty if none recorded).
+func WithLeadingTrivia(m *Map, n ast.Node) []Piece {
+	if a, ok := m.byNode[n]; ok {
+		return a.Leading
+	}
+	return nil
+}
+
+// WithTrailingTrivia returns n's trailing trivia (empty if none recorded).
+func WithTrailingTrivia(m *Map, n ast.Node) []Piece {
+	if a, ok := m.byNode[n]; ok {
+		return a.Trailing
+	}
+	return nil
+}